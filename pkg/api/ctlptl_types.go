@@ -0,0 +1,136 @@
+package api
+
+// TypeMeta describes an individual object sent to, or returned from, ctlptl.
+type TypeMeta struct {
+	Kind       string `json:"kind,omitempty"`
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+// Registry describes a Docker registry container managed by ctlptl,
+// normally used as a local pull-through cache or push target for a
+// dev cluster.
+type Registry struct {
+	TypeMeta `json:",inline"`
+
+	Name string `json:"name,omitempty"`
+
+	Port          int    `json:"port,omitempty"`
+	ListenAddress string `json:"listenAddress,omitempty"`
+	Image         string `json:"image,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Proxy configures the registry as a pull-through cache of a remote
+	// registry.
+	Proxy *RegistryProxySpec `json:"proxy,omitempty"`
+
+	// Auth configures basic-auth for the registry via htpasswd.
+	Auth *RegistryAuthSpec `json:"auth,omitempty"`
+
+	// TLS configures the registry to serve over HTTPS.
+	TLS *RegistryTLSSpec `json:"tls,omitempty"`
+
+	// Backend selects where the registry actually stores image data.
+	// One of "filesystem" (default), "s3", or "ipfs". "s3" is accepted,
+	// but runs the same container as "filesystem": no S3 storage
+	// settings are passed to it.
+	Backend string `json:"backend,omitempty"`
+
+	// IPFS configures the registry to store and serve image data from
+	// IPFS. Only used when Backend is "ipfs".
+	IPFS *RegistryIPFSSpec `json:"ipfs,omitempty"`
+
+	Status RegistryStatus `json:"status,omitempty"`
+}
+
+// RegistryIPFSSpec configures the IPFS-backed registry shim.
+type RegistryIPFSSpec struct {
+	// APIAddr is the base URL of the IPFS node's HTTP API, e.g.
+	// "http://127.0.0.1:5001".
+	APIAddr string `json:"apiAddr,omitempty"`
+}
+
+// RegistryAuthSpec configures basic-auth for the registry container using
+// an htpasswd file. Either HtpasswdFile can be set directly, or
+// Username/Password can be set and ctlptl will generate the htpasswd
+// file itself.
+type RegistryAuthSpec struct {
+	HtpasswdFile string `json:"htpasswdFile,omitempty"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+}
+
+// RegistryTLSSpec configures the registry container to terminate TLS
+// itself using the given certificate and key.
+type RegistryTLSSpec struct {
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+}
+
+// RegistryProxySpec configures the registry container to act as a
+// pull-through cache of a remote registry, rather than storing images
+// pushed directly to it.
+type RegistryProxySpec struct {
+	RemoteURL string `json:"remoteURL,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+	TTL       string `json:"ttl,omitempty"`
+}
+
+// RegistryStatus reports the observed state of the registry container.
+type RegistryStatus struct {
+	ContainerID string   `json:"containerID,omitempty"`
+	IPAddress   string   `json:"ipAddress,omitempty"`
+	HostPort    int      `json:"hostPort,omitempty"`
+	Networks    []string `json:"networks,omitempty"`
+
+	// CID is the IPFS CID of the most recently pushed manifest, when
+	// Backend is "ipfs".
+	CID string `json:"cid,omitempty"`
+}
+
+// Replication describes a periodic job that mirrors images from one
+// ctlptl registry to another, similar to Harbor's replication rules.
+type Replication struct {
+	TypeMeta `json:",inline"`
+
+	Name string `json:"name,omitempty"`
+
+	RegistryReplicationSpec `json:",inline"`
+
+	Status ReplicationStatus `json:"status,omitempty"`
+}
+
+// RegistryReplicationSpec configures one replication rule between two
+// ctlptl-managed registries.
+type RegistryReplicationSpec struct {
+	SourceRegistry      string `json:"sourceRegistry,omitempty"`
+	DestinationRegistry string `json:"destinationRegistry,omitempty"`
+
+	// Filter is a glob matched against "repo:tag" when deciding which
+	// images to replicate, e.g. "library/*:latest".
+	Filter string `json:"filter,omitempty"`
+
+	// Schedule is a standard 5-field cron expression. If empty,
+	// `ctlptl replication run` runs the replication once and exits, as
+	// it does with --once.
+	Schedule string `json:"schedule,omitempty"`
+
+	// Mode is either "push" (source pushes to destination on a
+	// schedule) or "pull-through" (destination pulls on demand,
+	// caching what it fetches). Defaults to "push".
+	Mode string `json:"mode,omitempty"`
+
+	// DeletePolicy controls whether images removed from the source are
+	// also removed from the destination. One of "keep" (default) or
+	// "mirror".
+	DeletePolicy string `json:"deletePolicy,omitempty"`
+}
+
+// ReplicationStatus reports the observed state of a Replication's last run.
+type ReplicationStatus struct {
+	LastRunTime   string `json:"lastRunTime,omitempty"`
+	LastRunError  string `json:"lastRunError,omitempty"`
+	ImagesCopied  int    `json:"imagesCopied,omitempty"`
+	ImagesSkipped int    `json:"imagesSkipped,omitempty"`
+}
@@ -39,7 +39,8 @@ func (o *CreateRegistryOptions) Command() *cobra.Command {
 		Example: "  ctlptl create registry ctlptl-registry\n" +
 			"  ctlptl create registry ctlptl-registry --port=5000\n" +
 			"  ctlptl create registry ctlptl-registry --port=5000 --listen-address 0.0.0.0\n" +
-			"  ctlptl create registry ctlptl-pull-through-registry --proxy-remote-url=https://registry-1.docker.io",
+			"  ctlptl create registry ctlptl-pull-through-registry --proxy-remote-url=https://registry-1.docker.io\n" +
+			"  ctlptl create registry ctlptl-registry --auth-username=me --auth-password=hunter2 --tls-cert=tls.crt --tls-key=tls.key",
 		Run:  o.Run,
 		Args: cobra.ExactArgs(1),
 	}
@@ -65,6 +66,28 @@ func (o *CreateRegistryOptions) Command() *cobra.Command {
 	cmd.Flags().StringVar(&proxyTTL, "proxy-ttl", "",
 		"The TTL for the pull-through proxy cache")
 
+	// Initialize Auth only if any auth-related flag is set
+	var authHtpasswdFile, authUsername, authPassword string
+	cmd.Flags().StringVar(&authHtpasswdFile, "auth-htpasswd-file", "",
+		"Path to an existing htpasswd file to use for registry basic-auth")
+	cmd.Flags().StringVar(&authUsername, "auth-username", "",
+		"Username for registry basic-auth; ctlptl will generate an htpasswd file for it")
+	cmd.Flags().StringVar(&authPassword, "auth-password", "",
+		"Password for registry basic-auth; ctlptl will generate an htpasswd file for it")
+
+	// Initialize TLS only if any tls-related flag is set
+	var tlsCert, tlsKey string
+	cmd.Flags().StringVar(&tlsCert, "tls-cert", "",
+		"Path to a TLS certificate to serve the registry with")
+	cmd.Flags().StringVar(&tlsKey, "tls-key", "",
+		"Path to the TLS certificate's private key")
+
+	var ipfsAPI string
+	cmd.Flags().StringVar(&o.Registry.Backend, "backend", registry.DefaultBackend,
+		"Where the registry stores image data: \"filesystem\", \"s3\", or \"ipfs\"")
+	cmd.Flags().StringVar(&ipfsAPI, "ipfs-api", "",
+		"Address of the IPFS node's HTTP API; only used when --backend=ipfs")
+
 	cmd.PreRun = func(cmd *cobra.Command, args []string) {
 		if proxyRemoteURL != "" {
 			o.Registry.Proxy = &api.RegistryProxySpec{
@@ -74,6 +97,22 @@ func (o *CreateRegistryOptions) Command() *cobra.Command {
 				TTL:       proxyTTL,
 			}
 		}
+		if authHtpasswdFile != "" || authUsername != "" || authPassword != "" {
+			o.Registry.Auth = &api.RegistryAuthSpec{
+				HtpasswdFile: authHtpasswdFile,
+				Username:     authUsername,
+				Password:     authPassword,
+			}
+		}
+		if tlsCert != "" || tlsKey != "" {
+			o.Registry.TLS = &api.RegistryTLSSpec{
+				CertFile: tlsCert,
+				KeyFile:  tlsKey,
+			}
+		}
+		if ipfsAPI != "" {
+			o.Registry.IPFS = &api.RegistryIPFSSpec{APIAddr: ipfsAPI}
+		}
 	}
 
 	return cmd
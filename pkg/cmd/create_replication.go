@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/replication"
+)
+
+type CreateReplicationOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+
+	Replication *api.Replication
+}
+
+func NewCreateReplicationOptions() *CreateReplicationOptions {
+	o := &CreateReplicationOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("created"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+		Replication: &api.Replication{
+			TypeMeta: replication.TypeMeta(),
+		},
+	}
+	return o
+}
+
+func (o *CreateReplicationOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "replication [name]",
+		Short: "Create a periodic replication between two registries",
+		Example: "  ctlptl create replication mirror-docker-hub \\\n" +
+			"    --source-registry=upstream-mirror --destination-registry=ctlptl-registry \\\n" +
+			"    --filter='library/*:latest' --schedule='0 * * * *'",
+		Run:  o.Run,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.Replication.SourceRegistry, "source-registry", "",
+		"The ctlptl registry to replicate images from")
+	cmd.Flags().StringVar(&o.Replication.DestinationRegistry, "destination-registry", "",
+		"The ctlptl registry to replicate images to")
+	cmd.Flags().StringVar(&o.Replication.Filter, "filter", "",
+		"A repo:tag glob restricting which images get replicated")
+	cmd.Flags().StringVar(&o.Replication.Schedule, "schedule", "",
+		"A 5-field cron schedule for `ctlptl replication run` to follow; omit to run once and exit, as with --once")
+	cmd.Flags().StringVar(&o.Replication.Mode, "mode", "push",
+		"How images move between registries: \"push\" or \"pull-through\"")
+	cmd.Flags().StringVar(&o.Replication.DeletePolicy, "delete-policy", "keep",
+		"Whether images removed from the source are also removed from the destination: \"keep\" or \"mirror\"")
+
+	return cmd
+}
+
+func (o *CreateReplicationOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := replication.DefaultController()
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type replicationCreator interface {
+	Apply(ctx context.Context, replication *api.Replication) (*api.Replication, error)
+	Get(ctx context.Context, name string) (*api.Replication, error)
+}
+
+func (o *CreateReplicationOptions) run(controller replicationCreator, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.create.replication", nil)
+	defer a.Flush(time.Second)
+
+	o.Replication.Name = name
+	replication.FillDefaults(o.Replication)
+
+	if o.Replication.SourceRegistry == "" || o.Replication.DestinationRegistry == "" {
+		return fmt.Errorf("Cannot create replication: --source-registry and --destination-registry are required")
+	}
+
+	ctx := context.Background()
+	_, err = controller.Get(ctx, o.Replication.Name)
+	if err == nil {
+		return fmt.Errorf("Cannot create replication: already exists")
+	} else if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("Cannot check replication: %v", err)
+	}
+
+	applied, err := controller.Apply(ctx, o.Replication)
+	if err != nil {
+		return err
+	}
+
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	return printer.PrintObj(applied, o.Out)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+// RegistryConvertOptions rewrites an IPFS CID into a Docker-safe base32
+// repository name. The ipfs-backed registry shim does not look content
+// up by such a name: it serves only what was pushed to it, under the
+// path it was pushed to.
+type RegistryConvertOptions struct {
+	genericclioptions.IOStreams
+}
+
+func NewRegistryConvertOptions() *RegistryConvertOptions {
+	return &RegistryConvertOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *RegistryConvertOptions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "convert [cid]",
+		Short:   "Rewrite an IPFS CID into a Docker-safe repository name",
+		Example: "  docker pull localhost:5000/$(ctlptl registry convert QmRootCID):latest",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(1),
+	}
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	return cmd
+}
+
+func (o *RegistryConvertOptions) Run(cmd *cobra.Command, args []string) {
+	err := o.run(args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (o *RegistryConvertOptions) run(cid string) error {
+	converted, err := registry.ConvertCID(cid)
+	if err != nil {
+		return fmt.Errorf("Cannot convert CID: %v", err)
+	}
+
+	_, _ = fmt.Fprintln(o.Out, converted)
+	return nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+// RegistryLoginOptions stores credentials for a ctlptl-managed registry,
+// modeled after `docker login` and `helm registry login`.
+type RegistryLoginOptions struct {
+	genericclioptions.IOStreams
+
+	Username      string
+	Password      string
+	PasswordStdin bool
+}
+
+func NewRegistryLoginOptions() *RegistryLoginOptions {
+	return &RegistryLoginOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *RegistryLoginOptions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login [name]",
+		Short: "Log in to a ctlptl-managed registry",
+		Example: "  ctlptl registry login ctlptl-registry --username=me --password=hunter2\n" +
+			"  cat password.txt | ctlptl registry login ctlptl-registry --username=me --password-stdin",
+		Run:  o.Run,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.Flags().StringVarP(&o.Username, "username", "u", "", "Username")
+	cmd.Flags().StringVarP(&o.Password, "password", "p", "", "Password")
+	cmd.Flags().BoolVar(&o.PasswordStdin, "password-stdin", false, "Take the password from stdin")
+
+	return cmd
+}
+
+func (o *RegistryLoginOptions) Run(cmd *cobra.Command, args []string) {
+	err := o.run(args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (o *RegistryLoginOptions) run(name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.registry.login", nil)
+	defer a.Flush(time.Second)
+
+	if o.PasswordStdin {
+		scanner := bufio.NewScanner(o.In)
+		if !scanner.Scan() {
+			return fmt.Errorf("Cannot read password from stdin: %v", scanner.Err())
+		}
+		o.Password = strings.TrimSpace(scanner.Text())
+	}
+	if o.Username == "" {
+		return fmt.Errorf("Cannot login: --username is required")
+	}
+	if o.Password == "" {
+		return fmt.Errorf("Cannot login: --password or --password-stdin is required")
+	}
+
+	store, err := registry.NewCredentialStore()
+	if err != nil {
+		return fmt.Errorf("Cannot login: %v", err)
+	}
+
+	err = store.Login(name, o.Username, o.Password)
+	if err != nil {
+		return fmt.Errorf("Cannot login: %v", err)
+	}
+
+	_, _ = fmt.Fprintf(o.Out, "Login Succeeded for %s\n", name)
+	return nil
+}
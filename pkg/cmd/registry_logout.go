@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+// RegistryLogoutOptions removes stored credentials for a ctlptl-managed
+// registry, modeled after `docker logout`.
+type RegistryLogoutOptions struct {
+	genericclioptions.IOStreams
+}
+
+func NewRegistryLogoutOptions() *RegistryLogoutOptions {
+	return &RegistryLogoutOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *RegistryLogoutOptions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "logout [name]",
+		Short:   "Log out of a ctlptl-managed registry",
+		Example: "  ctlptl registry logout ctlptl-registry",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(1),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+
+	return cmd
+}
+
+func (o *RegistryLogoutOptions) Run(cmd *cobra.Command, args []string) {
+	err := o.run(args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (o *RegistryLogoutOptions) run(name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.registry.logout", nil)
+	defer a.Flush(time.Second)
+
+	store, err := registry.NewCredentialStore()
+	if err != nil {
+		return fmt.Errorf("Cannot logout: %v", err)
+	}
+
+	err = store.Logout(name)
+	if err != nil {
+		return fmt.Errorf("Cannot logout: %v", err)
+	}
+
+	_, _ = fmt.Fprintf(o.Out, "Removed login credentials for %s\n", name)
+	return nil
+}
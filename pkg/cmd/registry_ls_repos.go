@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+// RegistryLsReposOptions lists every repository in a ctlptl-managed
+// registry.
+type RegistryLsReposOptions struct {
+	genericclioptions.IOStreams
+}
+
+func NewRegistryLsReposOptions() *RegistryLsReposOptions {
+	return &RegistryLsReposOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *RegistryLsReposOptions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls-repos [name]",
+		Short:   "List every repository in a registry",
+		Example: "  ctlptl registry ls-repos ctlptl-registry",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(1),
+	}
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	return cmd
+}
+
+func (o *RegistryLsReposOptions) Run(cmd *cobra.Command, args []string) {
+	err := o.run(args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (o *RegistryLsReposOptions) run(name string) error {
+	client, err := inspectClientForRegistry(name, o.IOStreams)
+	if err != nil {
+		return err
+	}
+
+	repos, err := client.Repositories(context.Background())
+	if err != nil {
+		return fmt.Errorf("Cannot list repositories: %v", err)
+	}
+
+	for _, repo := range repos {
+		_, _ = fmt.Fprintln(o.Out, repo)
+	}
+	return nil
+}
+
+// inspectClientForRegistry resolves name to its running container and
+// returns a client for talking to its Docker Registry V2 API.
+func inspectClientForRegistry(name string, iostreams genericclioptions.IOStreams) (*registry.InspectClient, error) {
+	controller, err := registry.DefaultController(iostreams)
+	if err != nil {
+		return nil, err
+	}
+	r, err := controller.Get(context.Background(), name)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot find registry %s: %v", name, err)
+	}
+
+	store, err := registry.NewCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.NewInspectClient(store, r), nil
+}
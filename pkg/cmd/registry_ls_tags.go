@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// RegistryLsTagsOptions lists every tag of a repository in a
+// ctlptl-managed registry.
+type RegistryLsTagsOptions struct {
+	genericclioptions.IOStreams
+}
+
+func NewRegistryLsTagsOptions() *RegistryLsTagsOptions {
+	return &RegistryLsTagsOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *RegistryLsTagsOptions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls-tags [name] [repo]",
+		Short:   "List every tag of a repository in a registry",
+		Example: "  ctlptl registry ls-tags ctlptl-registry library/nginx",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(2),
+	}
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	return cmd
+}
+
+func (o *RegistryLsTagsOptions) Run(cmd *cobra.Command, args []string) {
+	err := o.run(args[0], args[1])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (o *RegistryLsTagsOptions) run(name, repo string) error {
+	client, err := inspectClientForRegistry(name, o.IOStreams)
+	if err != nil {
+		return err
+	}
+
+	tags, err := client.Tags(context.Background(), repo)
+	if err != nil {
+		return fmt.Errorf("Cannot list tags: %v", err)
+	}
+
+	for _, tag := range tags {
+		_, _ = fmt.Fprintln(o.Out, tag)
+	}
+	return nil
+}
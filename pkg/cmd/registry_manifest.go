@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+// RegistryManifestOptions prints the manifest of a repo:tag in a
+// ctlptl-managed registry, optionally scanning each layer for known
+// vulnerabilities.
+type RegistryManifestOptions struct {
+	genericclioptions.IOStreams
+
+	Scan        bool
+	ScannerAddr string
+}
+
+func NewRegistryManifestOptions() *RegistryManifestOptions {
+	return &RegistryManifestOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *RegistryManifestOptions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest [name] [repo:tag]",
+		Short: "Print the manifest of an image in a registry",
+		Example: "  ctlptl registry manifest ctlptl-registry library/nginx:latest\n" +
+			"  ctlptl registry manifest ctlptl-registry library/nginx:latest --scan --scanner-addr=localhost:6060",
+		Run:  o.Run,
+		Args: cobra.ExactArgs(2),
+	}
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.Flags().BoolVar(&o.Scan, "scan", false, "Scan each layer for known vulnerabilities")
+	cmd.Flags().StringVar(&o.ScannerAddr, "scanner-addr", "", "Address of a Clair/Trivy-compatible gRPC scanner service")
+	return cmd
+}
+
+func (o *RegistryManifestOptions) Run(cmd *cobra.Command, args []string) {
+	err := o.run(args[0], args[1])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (o *RegistryManifestOptions) run(name, ref string) error {
+	repo, tag, ok := strings.Cut(ref, ":")
+	if !ok {
+		return fmt.Errorf("Cannot print manifest: expected repo:tag, got %q", ref)
+	}
+
+	client, err := inspectClientForRegistry(name, o.IOStreams)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	body, _, digest, err := client.Manifest(ctx, repo, tag)
+	if err != nil {
+		return fmt.Errorf("Cannot fetch manifest: %v", err)
+	}
+
+	_, _ = fmt.Fprintf(o.Out, "digest: %s\n", digest)
+	_, _ = fmt.Fprintln(o.Out, string(body))
+
+	if !o.Scan {
+		return nil
+	}
+	if o.ScannerAddr == "" {
+		return fmt.Errorf("Cannot scan: --scanner-addr is required with --scan")
+	}
+
+	return o.scanLayers(ctx, client, repo, body)
+}
+
+func (o *RegistryManifestOptions) scanLayers(ctx context.Context, client *registry.InspectClient, repo string, manifestBody []byte) error {
+	digests, err := registry.ManifestLayerDigests(manifestBody)
+	if err != nil {
+		return fmt.Errorf("Cannot scan: %v", err)
+	}
+
+	scanner, err := registry.NewScanner(o.ScannerAddr)
+	if err != nil {
+		return fmt.Errorf("Cannot scan: %v", err)
+	}
+	defer func() { _ = scanner.Close() }()
+
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "LAYER\tCVE\tSEVERITY\tPACKAGE\tFIXED")
+
+	for _, digest := range digests {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		blob, err := client.Blob(ctx, repo, digest)
+		if err != nil {
+			return fmt.Errorf("Cannot scan layer %s: %v", digest, err)
+		}
+
+		cves, err := scanner.ScanLayer(ctx, digest, blob)
+		_ = blob.Close()
+		if err != nil {
+			return fmt.Errorf("Cannot scan layer %s: %v", digest, err)
+		}
+
+		for _, cve := range cves {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", shortDigest(digest), cve.ID, cve.Severity, cve.Package, cve.FixedVer)
+		}
+	}
+
+	return w.Flush()
+}
+
+func shortDigest(digest string) string {
+	if len(digest) > 19 {
+		return digest[:19]
+	}
+	return digest
+}
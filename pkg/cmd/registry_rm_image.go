@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// RegistryRmImageOptions deletes a repo:tag from a ctlptl-managed
+// registry.
+type RegistryRmImageOptions struct {
+	genericclioptions.IOStreams
+}
+
+func NewRegistryRmImageOptions() *RegistryRmImageOptions {
+	return &RegistryRmImageOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *RegistryRmImageOptions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm-image [name] [repo:tag]",
+		Short:   "Delete an image from a registry",
+		Example: "  ctlptl registry rm-image ctlptl-registry library/nginx:latest",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(2),
+	}
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	return cmd
+}
+
+func (o *RegistryRmImageOptions) Run(cmd *cobra.Command, args []string) {
+	err := o.run(args[0], args[1])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (o *RegistryRmImageOptions) run(name, ref string) error {
+	repo, tag, ok := strings.Cut(ref, ":")
+	if !ok {
+		return fmt.Errorf("Cannot delete image: expected repo:tag, got %q", ref)
+	}
+
+	client, err := inspectClientForRegistry(name, o.IOStreams)
+	if err != nil {
+		return err
+	}
+
+	err = client.DeleteManifest(context.Background(), repo, tag)
+	if err != nil {
+		return fmt.Errorf("Cannot delete image: %v", err)
+	}
+
+	_, _ = fmt.Fprintf(o.Out, "Deleted %s\n", ref)
+	return nil
+}
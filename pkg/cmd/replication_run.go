@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+	"github.com/tilt-dev/ctlptl/pkg/replication"
+)
+
+// ReplicationRunOptions runs a previously-created Replication, either
+// once or forever on its configured schedule.
+type ReplicationRunOptions struct {
+	genericclioptions.IOStreams
+
+	Once bool
+}
+
+func NewReplicationRunOptions() *ReplicationRunOptions {
+	return &ReplicationRunOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *ReplicationRunOptions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run [name]",
+		Short: "Run a replication, copying images from its source registry to its destination registry",
+		Example: "  ctlptl replication run mirror-docker-hub --once\n" +
+			"  ctlptl replication run mirror-docker-hub",
+		Run:  o.Run,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.Flags().BoolVar(&o.Once, "once", false, "Run the replication a single time and exit, ignoring its schedule")
+
+	return cmd
+}
+
+func (o *ReplicationRunOptions) Run(cmd *cobra.Command, args []string) {
+	err := o.run(args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (o *ReplicationRunOptions) run(name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.replication.run", nil)
+	defer a.Flush(time.Second)
+
+	ctx := context.Background()
+
+	replications, err := replication.DefaultController()
+	if err != nil {
+		return err
+	}
+	spec, err := replications.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("Cannot run replication: %v", err)
+	}
+
+	registries, err := registry.DefaultController(o.IOStreams)
+	if err != nil {
+		return err
+	}
+
+	src, err := endpointForRegistry(ctx, registries, spec.SourceRegistry)
+	if err != nil {
+		return fmt.Errorf("Cannot run replication: resolving source registry: %v", err)
+	}
+	dst, err := endpointForRegistry(ctx, registries, spec.DestinationRegistry)
+	if err != nil {
+		return fmt.Errorf("Cannot run replication: resolving destination registry: %v", err)
+	}
+
+	syncer := replication.NewSyncer(o.ErrOut)
+
+	if o.Once || spec.Schedule == "" {
+		_, err := syncer.Run(ctx, spec.RegistryReplicationSpec, src, dst)
+		return err
+	}
+
+	for {
+		_, err := syncer.Run(ctx, spec.RegistryReplicationSpec, src, dst)
+		if err != nil {
+			_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		}
+
+		wait, err := replication.SleepDuration(spec.Schedule, time.Now())
+		if err != nil {
+			return fmt.Errorf("Cannot run replication: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func endpointForRegistry(ctx context.Context, registries *registry.Controller, name string) (replication.Endpoint, error) {
+	r, err := registries.Get(ctx, name)
+	if err != nil {
+		return replication.Endpoint{}, err
+	}
+
+	store, err := registry.NewCredentialStore()
+	if err != nil {
+		return replication.Endpoint{}, err
+	}
+	username, password, _ := store.Get(name)
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return replication.Endpoint{
+		URL:      fmt.Sprintf("%s://%s:%d", scheme, r.ListenAddress, r.Status.HostPort),
+		Username: username,
+		Password: password,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// htpasswdDir is where generated htpasswd files are written inside the
+// ctlptl config directory, keyed by registry name.
+const htpasswdDir = "registry-auth"
+
+// ensureHtpasswdFile makes sure the registry has an htpasswd file on disk,
+// generating one from Username/Password with bcrypt if the user didn't
+// point us at an existing file.
+func ensureHtpasswdFile(configDir string, r *api.Registry) (string, error) {
+	auth := r.Auth
+	if auth == nil {
+		return "", nil
+	}
+	if auth.HtpasswdFile != "" {
+		return auth.HtpasswdFile, nil
+	}
+	if auth.Username == "" || auth.Password == "" {
+		return "", fmt.Errorf("registry auth: must specify either htpasswdFile or username+password")
+	}
+
+	line, err := htpasswdLine(auth.Username, auth.Password)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(configDir, htpasswdDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("registry auth: %v", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s.htpasswd", r.Name))
+	if err := os.WriteFile(path, []byte(line+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("registry auth: %v", err)
+	}
+	return path, nil
+}
+
+// htpasswdLine generates a single bcrypt htpasswd entry, matching the
+// format the distribution registry's htpasswd auth handler expects.
+func htpasswdLine(username, password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("registry auth: generating htpasswd: %v", err)
+	}
+	return fmt.Sprintf("%s:%s", username, hash), nil
+}
@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"encoding/base32"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// base58Alphabet is the base58btc alphabet IPFS uses to encode CIDv0
+// ("Qm..."-style) CIDs.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// cidV1DagPBCodec is the multicodec for dag-pb, the implicit codec of
+// every CIDv0.
+const cidV1DagPBCodec = 0x70
+
+// ConvertCID rewrites an IPFS CID into a Docker-safe repository name: a
+// lowercase, base32-encoded CIDv1. Docker repository names only allow
+// lowercase letters, digits, and a handful of separators, but CIDv0's
+// base58 encoding is mixed-case, so `docker pull` can't address it
+// directly. This mirrors the approach the ipdr project uses to let an
+// unmodified Docker client pull IPFS content by CID.
+func ConvertCID(cid string) (string, error) {
+	if len(cid) > 0 && (cid[0] == 'b' || cid[0] == 'B') {
+		// Already a multibase-base32 CIDv1 (lower or upper); base32's
+		// alphabet is already Docker-safe once lowercased. CIDv0
+		// never starts with a 'b'/'B', since its fixed sha2-256
+		// multihash prefix always base58-encodes to "Qm...".
+		return strings.ToLower(cid), nil
+	}
+
+	mh, err := decodeBase58(cid)
+	if err != nil {
+		return "", fmt.Errorf("converting CID %q: %v", cid, err)
+	}
+
+	// A CIDv1 is <version><codec><multihash>, each of the first two as
+	// unsigned varints. CIDv0 is always a bare sha2-256 multihash, so
+	// version and codec are both single bytes here.
+	v1 := append([]byte{0x01, cidV1DagPBCodec}, mh...)
+
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	return "b" + strings.ToLower(enc.EncodeToString(v1)), nil
+}
+
+// decodeBase58 decodes s using the base58btc alphabet, preserving
+// leading zero bytes (encoded as leading '1's) that big.Int would
+// otherwise drop.
+func decodeBase58(s string) ([]byte, error) {
+	leadingZeros := 0
+	for _, r := range s {
+		if r != '1' {
+			break
+		}
+		leadingZeros++
+	}
+
+	n := new(big.Int)
+	base := big.NewInt(int64(len(base58Alphabet)))
+	for _, r := range s {
+		idx := strings.IndexRune(base58Alphabet, r)
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+
+	decoded := n.Bytes()
+	return append(make([]byte, leadingZeros), decoded...), nil
+}
@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/base32"
+	"strings"
+	"testing"
+)
+
+func TestConvertCID_PassthroughV1(t *testing.T) {
+	in := "BAFYBEIGDYRZT5SFP7UDM7HU76UH7Y26NF3EFUYLQABF3OCLGTQY55FBZDI"
+	got, err := ConvertCID(in)
+	if err != nil {
+		t.Fatalf("ConvertCID(%q) returned error: %v", in, err)
+	}
+	if want := strings.ToLower(in); got != want {
+		t.Errorf("ConvertCID(%q) = %q, want %q", in, got, want)
+	}
+}
+
+func TestConvertCID_V0ToV1(t *testing.T) {
+	// Built entirely from base58Alphabet characters, so it's guaranteed
+	// to decode; it doesn't need to be a real IPFS CID for this test.
+	in := "11" + base58Alphabet[:20]
+
+	got, err := ConvertCID(in)
+	if err != nil {
+		t.Fatalf("ConvertCID(%q) returned error: %v", in, err)
+	}
+	if !strings.HasPrefix(got, "b") {
+		t.Fatalf("ConvertCID(%q) = %q, want a \"b\"-prefixed CIDv1", in, got)
+	}
+	if got != strings.ToLower(got) {
+		t.Fatalf("ConvertCID(%q) = %q, want an all-lowercase result", in, got)
+	}
+
+	raw, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(got[1:]))
+	if err != nil {
+		t.Fatalf("decoding ConvertCID(%q) output %q: %v", in, got, err)
+	}
+	if len(raw) < 2 || raw[0] != 0x01 || raw[1] != cidV1DagPBCodec {
+		t.Fatalf("ConvertCID(%q) multibase payload = %x, want a version-1/dag-pb prefix", in, raw)
+	}
+
+	wantMH, err := decodeBase58(in)
+	if err != nil {
+		t.Fatalf("decodeBase58(%q): %v", in, err)
+	}
+	if !bytes.Equal(raw[2:], wantMH) {
+		t.Fatalf("ConvertCID(%q) multihash = %x, want %x", in, raw[2:], wantMH)
+	}
+}
+
+func TestConvertCID_InvalidBase58(t *testing.T) {
+	if _, err := ConvertCID("not-valid-0OIl"); err == nil {
+		t.Fatal("ConvertCID of an invalid base58 string should have returned an error")
+	}
+}
@@ -0,0 +1,150 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// containerMount is a host path bind-mounted into the registry container.
+type containerMount struct {
+	HostPath      string
+	ContainerPath string
+	ReadOnly      bool
+}
+
+// containerConfig is the set of env vars and mounts needed to start a
+// registry container matching the given spec's auth and TLS settings.
+type containerConfig struct {
+	Env    []string
+	Mounts []containerMount
+}
+
+// newContainerConfig builds the env vars and mounts the distribution
+// registry image needs to honor r's Auth and TLS settings. htpasswdPath
+// is the on-disk htpasswd file to mount, as returned by
+// ensureHtpasswdFile.
+func newContainerConfig(r *api.Registry, htpasswdPath string) (containerConfig, error) {
+	cfg := containerConfig{
+		// `ctlptl registry rm-image` deletes manifests via the registry's
+		// DELETE API, which the distribution image refuses to serve
+		// unless this is set.
+		Env: []string{"REGISTRY_STORAGE_DELETE_ENABLED=true"},
+	}
+
+	if r.Auth != nil {
+		if htpasswdPath == "" {
+			return cfg, fmt.Errorf("registry auth: no htpasswd file available")
+		}
+		const containerHtpasswdPath = "/auth/htpasswd"
+		cfg.Env = append(cfg.Env,
+			"REGISTRY_AUTH=htpasswd",
+			"REGISTRY_AUTH_HTPASSWD_REALM=Registry Realm",
+			fmt.Sprintf("REGISTRY_AUTH_HTPASSWD_PATH=%s", containerHtpasswdPath),
+		)
+		cfg.Mounts = append(cfg.Mounts, containerMount{
+			HostPath:      htpasswdPath,
+			ContainerPath: containerHtpasswdPath,
+			ReadOnly:      true,
+		})
+	}
+
+	if r.TLS != nil {
+		const containerCertPath = "/certs/tls.crt"
+		const containerKeyPath = "/certs/tls.key"
+		cfg.Env = append(cfg.Env,
+			fmt.Sprintf("REGISTRY_HTTP_TLS_CERTIFICATE=%s", containerCertPath),
+			fmt.Sprintf("REGISTRY_HTTP_TLS_KEY=%s", containerKeyPath),
+		)
+		cfg.Mounts = append(cfg.Mounts,
+			containerMount{HostPath: r.TLS.CertFile, ContainerPath: containerCertPath, ReadOnly: true},
+			containerMount{HostPath: r.TLS.KeyFile, ContainerPath: containerKeyPath, ReadOnly: true},
+		)
+	}
+
+	return cfg, nil
+}
+
+// containerNamePrefix namespaces the Docker containers ctlptl starts for
+// registries, so they're easy to spot (and clean up) alongside whatever
+// else is running on the host's daemon.
+const containerNamePrefix = "ctlptl-registry-"
+
+// containerName returns the name ctlptl gives the Docker container
+// backing registry r.
+func containerName(r *api.Registry) string {
+	return containerNamePrefix + r.Name
+}
+
+// runContainer starts the Docker container backing r, applying cfg's env
+// vars and mounts, and returns its container ID. It shells out to the
+// `docker` CLI rather than a Docker SDK, since that's the only Docker
+// integration available without vendoring a new dependency.
+func runContainer(r *api.Registry, cfg containerConfig) (string, error) {
+	name := containerName(r)
+
+	// Replace any previous container under this name, so re-applying a
+	// registry (e.g. to pick up new auth/TLS settings) doesn't collide
+	// with the one it's replacing.
+	_ = exec.Command("docker", "rm", "-f", name).Run()
+
+	args := []string{
+		"run", "-d",
+		"--name", name,
+		"-p", fmt.Sprintf("%s:%d:5000", r.ListenAddress, r.Port),
+	}
+	for _, kv := range cfg.Env {
+		args = append(args, "-e", kv)
+	}
+	for _, m := range cfg.Mounts {
+		mount := fmt.Sprintf("%s:%s", m.HostPath, m.ContainerPath)
+		if m.ReadOnly {
+			mount += ":ro"
+		}
+		args = append(args, "-v", mount)
+	}
+	args = append(args, r.Image)
+
+	out, err := exec.Command("docker", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("starting container: %v", dockerRunError(err))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// containerHostPort asks Docker what host port it assigned to name's
+// published 5000/tcp, so callers can report the real port even when
+// r.Port was 0 (Docker picks a random one in that case).
+func containerHostPort(name string) (int, error) {
+	out, err := exec.Command("docker", "port", name, "5000/tcp").Output()
+	if err != nil {
+		return 0, fmt.Errorf("inspecting container port: %v", dockerRunError(err))
+	}
+	// Output looks like "0.0.0.0:32768", possibly with one line per
+	// listen address; any of them report the same host port.
+	line := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	idx := strings.LastIndex(line, ":")
+	if idx < 0 {
+		return 0, fmt.Errorf("inspecting container port: unexpected output %q", line)
+	}
+	port, err := strconv.Atoi(line[idx+1:])
+	if err != nil {
+		return 0, fmt.Errorf("inspecting container port: unexpected output %q", line)
+	}
+	return port, nil
+}
+
+// dockerRunError unwraps an *exec.ExitError's stderr, if any, so callers
+// surface the Docker CLI's own complaint instead of just "exit status 1".
+func dockerRunError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%s", bytes.TrimSpace(exitErr.Stderr))
+	}
+	return err
+}
@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// DefaultRegistryImageRef is the image used for registry containers when
+// the user doesn't specify one explicitly.
+const DefaultRegistryImageRef = "docker.io/library/registry:2"
+
+// DefaultBackend is the storage backend used for registry containers
+// when the user doesn't specify one explicitly.
+const DefaultBackend = "filesystem"
+
+// registriesDir is the subdirectory of the ctlptl config dir where
+// Registry specs are persisted, keyed by name, mirroring how
+// pkg/replication.Controller persists Replication specs.
+const registriesDir = "registries"
+
+// TypeMeta returns the TypeMeta that should be stamped on Registry objects
+// created by this package.
+func TypeMeta() api.TypeMeta {
+	return api.TypeMeta{Kind: "Registry", APIVersion: "ctlptl.dev/v1alpha1"}
+}
+
+// FillDefaults fills in any fields on the Registry that weren't set
+// explicitly by the user.
+func FillDefaults(r *api.Registry) {
+	if r.Image == "" {
+		r.Image = DefaultRegistryImageRef
+	}
+	if r.ListenAddress == "" {
+		r.ListenAddress = "127.0.0.1"
+	}
+	if r.Backend == "" {
+		r.Backend = DefaultBackend
+	}
+}
+
+// validBackends are the Backend values ctlptl knows how to serve a
+// registry's image data from.
+var validBackends = map[string]bool{
+	"filesystem": true,
+	"s3":         true,
+	"ipfs":       true,
+}
+
+// validateBackend checks that r.Backend (after FillDefaults) is one
+// ctlptl supports. Backend-specific config (e.g. IPFS.APIAddr) is
+// validated by that backend's own constructor instead, so there's one
+// place that knows what each backend requires.
+func validateBackend(r *api.Registry) error {
+	if !validBackends[r.Backend] {
+		return fmt.Errorf("registry backend: unrecognized backend %q", r.Backend)
+	}
+	return nil
+}
+
+// Controller manages the registry specs ctlptl knows about.
+//
+// For the "filesystem" and "s3" backends, Apply starts the registry
+// container through the `docker` CLI (see container.go) and then
+// persists the spec; Get only reads the persisted spec back. The
+// "ipfs" backend doesn't use a container -- Apply runs it as an
+// in-process HTTP shim instead (see ipfsshim.go).
+type Controller struct {
+	iostreams genericclioptions.IOStreams
+	dir       string
+}
+
+// DefaultController creates a Controller backed by the default ctlptl
+// config directory.
+func DefaultController(iostreams genericclioptions.IOStreams) (*Controller, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Controller{iostreams: iostreams, dir: filepath.Join(dir, registriesDir)}, nil
+}
+
+var registryGroupResource = schema.GroupResource{Group: "ctlptl.dev", Resource: "registries"}
+
+func (c *Controller) path(name string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s.json", name))
+}
+
+// Get looks up a previously-applied registry spec by name.
+func (c *Controller) Get(ctx context.Context, name string) (*api.Registry, error) {
+	data, err := os.ReadFile(c.path(name))
+	if os.IsNotExist(err) {
+		return nil, errors.NewNotFound(registryGroupResource, name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	r := &api.Registry{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("reading registry %s: %v", name, err)
+	}
+	return r, nil
+}
+
+// Apply creates or updates the registry to match the given spec.
+func (c *Controller) Apply(ctx context.Context, r *api.Registry) (*api.Registry, error) {
+	if err := validateBackend(r); err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+
+	dir, err := configDir()
+	if err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+
+	htpasswdPath, err := ensureHtpasswdFile(dir, r)
+	if err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+
+	cfg, err := newContainerConfig(r, htpasswdPath)
+	if err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+
+	store, err := NewCredentialStore()
+	if err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+	if err := writeClusterPullConfig(dir, store, r); err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+
+	if r.Backend == "ipfs" {
+		if err := c.startIPFSShim(r); err != nil {
+			return nil, fmt.Errorf("creating registry: %v", err)
+		}
+	} else {
+		containerID, err := runContainer(r, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("creating registry: %v", err)
+		}
+		r.Status.ContainerID = containerID
+
+		hostPort := r.Port
+		if hostPort == 0 {
+			hostPort, err = containerHostPort(containerName(r))
+			if err != nil {
+				return nil, fmt.Errorf("creating registry: %v", err)
+			}
+		}
+		r.Status.HostPort = hostPort
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+	data, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+	if err := os.WriteFile(c.path(r.Name), data, 0o600); err != nil {
+		return nil, fmt.Errorf("creating registry: %v", err)
+	}
+
+	return r, nil
+}
+
+// startIPFSShim starts r's IPFS-backed Docker Registry V2 shim on
+// r.ListenAddress:r.Port (choosing a free port if r.Port is 0) and
+// records the bound port on r.Status.HostPort. The shim only runs for
+// the lifetime of the current process -- there's no daemonization, so
+// it only serves traffic for as long as whatever called Apply (e.g.
+// `ctlptl create registry`) stays running.
+func (c *Controller) startIPFSShim(r *api.Registry) error {
+	shim, err := NewIPFSShim(r)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf("%s:%d", r.ListenAddress, r.Port))
+	if err != nil {
+		return fmt.Errorf("starting ipfs shim: %v", err)
+	}
+	r.Status.HostPort = ln.Addr().(*net.TCPAddr).Port
+
+	go func() {
+		_ = http.Serve(ln, shim)
+	}()
+	return nil
+}
@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// credentialConfigFile is the name of the ctlptl-managed credential store,
+// written in the same format as Docker's ~/.docker/config.json so that
+// other tooling can read it directly.
+const credentialConfigFile = "config.json"
+
+// CredentialStore persists registry basic-auth credentials across ctlptl
+// invocations, keyed by registry name, the same way `docker login` keys
+// its config.json by registry host.
+type CredentialStore struct {
+	path string
+}
+
+// NewCredentialStore opens the default ctlptl credential store at
+// $XDG_CONFIG_HOME/ctlptl/config.json (or ~/.config/ctlptl/config.json).
+func NewCredentialStore() (*CredentialStore, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	return &CredentialStore{path: filepath.Join(dir, credentialConfigFile)}, nil
+}
+
+// load reads the store's config.json, tolerating a missing file.
+func (s *CredentialStore) load() (dockerConfigJSON, error) {
+	cfg := dockerConfigJSON{Auths: map[string]dockerConfigEntry{}}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.Auths == nil {
+		cfg.Auths = map[string]dockerConfigEntry{}
+	}
+	return cfg, nil
+}
+
+func (s *CredentialStore) save(cfg dockerConfigJSON) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+// Login records username/password credentials for the named registry.
+func (s *CredentialStore) Login(name, username, password string) error {
+	cfg, err := s.load()
+	if err != nil {
+		return err
+	}
+	cfg.Auths[name] = dockerConfigEntry{
+		Username: username,
+		Password: password,
+		Auth:     basicAuthToken(username, password),
+	}
+	return s.save(cfg)
+}
+
+// Logout removes any stored credentials for the named registry.
+func (s *CredentialStore) Logout(name string) error {
+	cfg, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(cfg.Auths, name)
+	return s.save(cfg)
+}
+
+// Get returns the stored credentials for the named registry, if any.
+func (s *CredentialStore) Get(name string) (username, password string, ok bool) {
+	cfg, err := s.load()
+	if err != nil {
+		return "", "", false
+	}
+	entry, ok := cfg.Auths[name]
+	if !ok {
+		return "", "", false
+	}
+	return entry.Username, entry.Password, true
+}
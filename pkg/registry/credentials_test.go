@@ -0,0 +1,42 @@
+package registry
+
+import "testing"
+
+func TestCredentialStore_LoginGetLogout(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	store, err := NewCredentialStore()
+	if err != nil {
+		t.Fatalf("NewCredentialStore: %v", err)
+	}
+
+	if _, _, ok := store.Get("ctlptl-registry"); ok {
+		t.Fatal("Get on a registry that was never logged in should return ok=false")
+	}
+
+	if err := store.Login("ctlptl-registry", "me", "hunter2"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	gotUser, gotPass, ok := store.Get("ctlptl-registry")
+	if !ok || gotUser != "me" || gotPass != "hunter2" {
+		t.Fatalf("Get after Login = (%q, %q, %v), want (\"me\", \"hunter2\", true)", gotUser, gotPass, ok)
+	}
+
+	// A second store instance, simulating a later `ctlptl` invocation,
+	// should see the same persisted credentials.
+	store2, err := NewCredentialStore()
+	if err != nil {
+		t.Fatalf("NewCredentialStore: %v", err)
+	}
+	if _, _, ok := store2.Get("ctlptl-registry"); !ok {
+		t.Fatal("credentials didn't persist across CredentialStore instances")
+	}
+
+	if err := store.Logout("ctlptl-registry"); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, _, ok := store.Get("ctlptl-registry"); ok {
+		t.Fatal("Get after Logout should return ok=false")
+	}
+}
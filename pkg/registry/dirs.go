@@ -0,0 +1,21 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// configDir returns the directory ctlptl uses to stash registry-related
+// state that doesn't belong in the Registry spec itself, such as
+// generated htpasswd files.
+func configDir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "ctlptl"), nil
+}
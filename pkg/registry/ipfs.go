@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// IPFSClient talks to an IPFS node's HTTP API to back a registry whose
+// Backend is "ipfs": pushes are content-addressed via `ipfs add`, and
+// pulls are served by `ipfs cat`.
+type IPFSClient struct {
+	apiAddr    string
+	httpClient *http.Client
+}
+
+// NewIPFSClient builds an IPFSClient pointed at r's configured IPFS API
+// endpoint (the value of `ctlptl create registry --ipfs-api`).
+func NewIPFSClient(r *api.Registry) (*IPFSClient, error) {
+	if r.IPFS == nil || r.IPFS.APIAddr == "" {
+		return nil, fmt.Errorf("ipfs backend: no --ipfs-api endpoint configured")
+	}
+	return &IPFSClient{apiAddr: r.IPFS.APIAddr, httpClient: http.DefaultClient}, nil
+}
+
+// Add stores data's contents in IPFS and returns the resulting CID.
+func (c *IPFSClient) Add(ctx context.Context, data io.Reader) (string, error) {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	part, err := mw.CreateFormFile("file", "blob")
+	if err != nil {
+		return "", fmt.Errorf("ipfs add: %v", err)
+	}
+	if _, err := io.Copy(part, data); err != nil {
+		return "", fmt.Errorf("ipfs add: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("ipfs add: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiAddr+"/api/v0/add", body)
+	if err != nil {
+		return "", fmt.Errorf("ipfs add: %v", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ipfs add: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ipfs add: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Hash string `json:"Hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("ipfs add: %v", err)
+	}
+	return result.Hash, nil
+}
+
+// Cat fetches the raw bytes stored under cid.
+func (c *IPFSClient) Cat(ctx context.Context, cid string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/api/v0/cat?arg=%s", c.apiAddr, cid), nil)
+	if err != nil {
+		return nil, fmt.Errorf("ipfs cat: %v", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ipfs cat: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("ipfs cat %s: status %d", cid, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
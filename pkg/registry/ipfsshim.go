@@ -0,0 +1,125 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// IPFSShim is an http.Handler that serves a Docker Registry V2 API
+// backed by IPFS: GETs against a blob or manifest are translated into
+// `ipfs cat` calls against whatever CID was last pushed to that exact
+// path, and PUTs are translated into `ipfs add`, with the resulting
+// CID recorded for that path. Manifest pushes also write their CID
+// back to Status.CID, as the registry's current head.
+//
+// Each path is tracked independently so a manifest GET and its blobs'
+// GETs each return their own content, and pushes to different repos
+// and tags don't overwrite each other. There's no catalog or tag
+// listing, and Status.CID only records the most recently pushed
+// manifest.
+type IPFSShim struct {
+	client *IPFSClient
+
+	mu        sync.Mutex
+	registry  *api.Registry
+	cids      map[string]string // V2 API path -> CID last pushed to it
+	uploadSeq int
+}
+
+// NewIPFSShim builds a shim that serves r's blobs and manifests from
+// the IPFS node configured on r.IPFS.
+func NewIPFSShim(r *api.Registry) (*IPFSShim, error) {
+	client, err := NewIPFSClient(r)
+	if err != nil {
+		return nil, err
+	}
+	return &IPFSShim{client: client, registry: r, cids: map[string]string{}}, nil
+}
+
+func (s *IPFSShim) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case req.Method == http.MethodGet && (strings.Contains(req.URL.Path, "/blobs/") || strings.Contains(req.URL.Path, "/manifests/")):
+		s.serveCat(req.Context(), w, req.URL.Path)
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/blobs/uploads/"):
+		s.serveStartUpload(w, req)
+	case req.Method == http.MethodPut:
+		s.servePut(req.Context(), w, req)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// serveStartUpload handles the first step of the Docker Registry V2 blob
+// push protocol: POST /v2/<name>/blobs/uploads/ opens an upload session
+// and returns its location for the client to PUT the blob's contents to.
+// The shim doesn't track upload sessions beyond that -- servePut/putKey
+// resolve the PUT's digest back to the blob's real path directly -- so
+// the session token just needs to be unique enough that concurrent
+// uploads don't collide.
+func (s *IPFSShim) serveStartUpload(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	s.uploadSeq++
+	seq := s.uploadSeq
+	s.mu.Unlock()
+
+	location := fmt.Sprintf("%s%d", req.URL.Path, seq)
+	w.Header().Set("Location", location)
+	w.Header().Set("Docker-Upload-UUID", fmt.Sprintf("%d", seq))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *IPFSShim) serveCat(ctx context.Context, w http.ResponseWriter, path string) {
+	s.mu.Lock()
+	cid := s.cids[path]
+	s.mu.Unlock()
+	if cid == "" {
+		http.Error(w, "no content pushed to this path yet", http.StatusNotFound)
+		return
+	}
+
+	body, err := s.client.Cat(ctx, cid)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("ipfs shim: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+	_, _ = io.Copy(w, body)
+}
+
+func (s *IPFSShim) servePut(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	cid, err := s.client.Add(ctx, req.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("ipfs shim: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	key := putKey(req)
+	s.mu.Lock()
+	s.cids[key] = cid
+	if strings.Contains(key, "/manifests/") {
+		s.registry.Status.CID = cid
+	}
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+}
+
+// putKey returns the path a later GET will use to fetch what's being
+// pushed by req. Manifest pushes PUT and GET the same path, but blob
+// pushes PUT to a per-upload-session path with the blob's digest only
+// available as a query parameter, while GETs address the blob directly
+// by digest -- so for those, putKey rewrites the path to match.
+func putKey(req *http.Request) string {
+	path := req.URL.Path
+	if idx := strings.Index(path, "/blobs/uploads/"); idx >= 0 {
+		if digest := req.URL.Query().Get("digest"); digest != "" {
+			return path[:idx] + "/blobs/" + digest
+		}
+	}
+	return path
+}
@@ -0,0 +1,32 @@
+package registry
+
+import "encoding/json"
+
+// manifestV2 is the minimal subset of the Docker/OCI image manifest
+// schema needed to enumerate an image's layer blobs for scanning.
+type manifestV2 struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Layers []struct {
+		Digest string `json:"digest"`
+	} `json:"layers"`
+}
+
+// ManifestLayerDigests parses a manifest body and returns the digest of
+// its config blob followed by each layer's digest, in order.
+func ManifestLayerDigests(body []byte) ([]string, error) {
+	var m manifestV2
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+
+	digests := make([]string, 0, len(m.Layers)+1)
+	if m.Config.Digest != "" {
+		digests = append(digests, m.Config.Digest)
+	}
+	for _, l := range m.Layers {
+		digests = append(digests, l.Digest)
+	}
+	return digests, nil
+}
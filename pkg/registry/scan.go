@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry/scanpb"
+)
+
+// CVE is a single vulnerability reported by a scanner backend for one
+// image layer.
+type CVE struct {
+	ID           string
+	Severity     string
+	Package      string
+	InstalledVer string
+	FixedVer     string
+}
+
+// Scanner streams image layers to an external Clair/Trivy-compatible
+// gRPC scanning service and collects the CVEs each one reports.
+type Scanner struct {
+	conn   *grpc.ClientConn
+	client scanpb.ScannerClient
+}
+
+// NewScanner dials the scanner service at addr (the value of
+// `ctlptl registry manifest --scanner-addr`).
+func NewScanner(addr string) (*Scanner, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to scanner at %s: %v", addr, err)
+	}
+	return &Scanner{conn: conn, client: scanpb.NewScannerClient(conn)}, nil
+}
+
+func (s *Scanner) Close() error {
+	return s.conn.Close()
+}
+
+// ScanLayer streams a single layer's blob to the scanner and returns the
+// CVEs it reports. The stream is aborted if ctx is canceled, so large
+// image scans can be stopped cleanly mid-transfer.
+func (s *Scanner) ScanLayer(ctx context.Context, digest string, blob io.Reader) ([]CVE, error) {
+	stream, err := s.client.ScanLayer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %v", digest, err)
+	}
+
+	buf := make([]byte, 1<<20)
+	for {
+		n, err := blob.Read(buf)
+		if n > 0 {
+			sendErr := stream.Send(&scanpb.LayerChunk{Digest: digest, Data: buf[:n]})
+			if sendErr != nil {
+				return nil, fmt.Errorf("scanning %s: %v", digest, sendErr)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("scanning %s: reading layer: %v", digest, err)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	report, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %v", digest, err)
+	}
+
+	cves := make([]CVE, 0, len(report.Cves))
+	for _, c := range report.Cves {
+		cves = append(cves, CVE{
+			ID:           c.Id,
+			Severity:     c.Severity,
+			Package:      c.Package,
+			InstalledVer: c.InstalledVersion,
+			FixedVer:     c.FixedVersion,
+		})
+	}
+	return cves, nil
+}
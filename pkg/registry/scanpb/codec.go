@@ -0,0 +1,29 @@
+package scanpb
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+const jsonCodecName = "json"
+
+// jsonCodec lets the scanner stream use plain JSON messages instead of
+// protobuf, since scanpb's types aren't generated from a .proto file.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return jsonCodecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
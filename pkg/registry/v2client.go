@@ -0,0 +1,156 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// InspectClient speaks the Docker Registry HTTP API V2 against the container
+// a Controller manages, for `ctlptl registry ls-repos/ls-tags/manifest/
+// rm-image`.
+type InspectClient struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// NewInspectClient builds an InspectClient pointed at r's container, honoring
+// whatever basic-auth credentials are configured for it (either inline
+// on the spec or saved via `ctlptl registry login`).
+func NewInspectClient(store *CredentialStore, r *api.Registry) *InspectClient {
+	username, password, _ := registryCredentials(store, r)
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return &InspectClient{
+		baseURL:    fmt.Sprintf("%s://%s:%d", scheme, r.ListenAddress, r.Status.HostPort),
+		username:   username,
+		password:   password,
+		httpClient: http.DefaultClient,
+	}
+}
+
+func (c *InspectClient) do(ctx context.Context, method, path string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.httpClient.Do(req)
+}
+
+// Repositories lists every repository in the registry's _catalog.
+func (c *InspectClient) Repositories(ctx context.Context) ([]string, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/v2/_catalog?n=1000", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing repositories: status %d", resp.StatusCode)
+	}
+
+	var page struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+	return page.Repositories, nil
+}
+
+// Tags lists every tag of repo.
+func (c *InspectClient) Tags(ctx context.Context, repo string) ([]string, error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/tags/list", repo), "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing tags for %s: status %d", repo, resp.StatusCode)
+	}
+
+	var page struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+	return page.Tags, nil
+}
+
+// Manifest fetches the raw manifest for repo:reference, along with its
+// content-type and digest.
+func (c *InspectClient) Manifest(ctx context.Context, repo, reference string) (body []byte, mediaType, digest string, err error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/manifests/%s", repo, reference),
+		"application/vnd.docker.distribution.manifest.v2+json")
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", "", fmt.Errorf("fetching manifest %s:%s: status %d", repo, reference, resp.StatusCode)
+	}
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return body, resp.Header.Get("Content-Type"), resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// Blob fetches the raw contents of a blob by digest, for scanning.
+func (c *InspectClient) Blob(ctx context.Context, repo, digest string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/blobs/%s", repo, digest), "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching blob %s@%s: status %d", repo, digest, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// DeleteManifest removes repo:reference from the registry. The registry
+// container must have been started with
+// REGISTRY_STORAGE_DELETE_ENABLED=true for this to succeed.
+func (c *InspectClient) DeleteManifest(ctx context.Context, repo, reference string) error {
+	// The delete API takes a digest, not a tag, so resolve the tag to
+	// its digest first if needed.
+	_, _, digest, err := c.Manifest(ctx, repo, reference)
+	if err != nil {
+		return err
+	}
+	if digest == "" {
+		return fmt.Errorf("deleting %s:%s: no such manifest", repo, reference)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("%s/v2/%s/manifests/%s", c.baseURL, repo, digest), nil)
+	if err != nil {
+		return err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("deleting %s:%s: status %d", repo, reference, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// replicationsDir is the subdirectory of the ctlptl config dir where
+// Replication specs are persisted, keyed by name.
+const replicationsDir = "replications"
+
+// TypeMeta returns the TypeMeta that should be stamped on Replication
+// objects created by this package.
+func TypeMeta() api.TypeMeta {
+	return api.TypeMeta{Kind: "Replication", APIVersion: "ctlptl.dev/v1alpha1"}
+}
+
+// FillDefaults fills in any fields on the Replication that weren't set
+// explicitly by the user.
+func FillDefaults(r *api.Replication) {
+	if r.Mode == "" {
+		r.Mode = "push"
+	}
+	if r.DeletePolicy == "" {
+		r.DeletePolicy = "keep"
+	}
+}
+
+var replicationGroupResource = schema.GroupResource{Group: "ctlptl.dev", Resource: "replications"}
+
+// Controller manages the lifecycle of Replication specs. Unlike the
+// registry Controller, there's no container to run here -- a Replication
+// is just a persisted spec that `ctlptl replication run` reads by name,
+// so Apply/Get simply read and write it to disk.
+type Controller struct {
+	dir string
+}
+
+// DefaultController creates a Controller backed by the default ctlptl
+// config directory.
+func DefaultController() (*Controller, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Controller{dir: filepath.Join(dir, replicationsDir)}, nil
+}
+
+func (c *Controller) path(name string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s.json", name))
+}
+
+// Get looks up a replication spec by name.
+func (c *Controller) Get(ctx context.Context, name string) (*api.Replication, error) {
+	data, err := os.ReadFile(c.path(name))
+	if os.IsNotExist(err) {
+		return nil, errors.NewNotFound(replicationGroupResource, name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	r := &api.Replication{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("reading replication %s: %v", name, err)
+	}
+	return r, nil
+}
+
+// Apply creates or updates the replication spec on disk.
+func (c *Controller) Apply(ctx context.Context, r *api.Replication) (*api.Replication, error) {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(c.path(r.Name), data, 0o600); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
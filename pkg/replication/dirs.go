@@ -0,0 +1,21 @@
+package replication
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// configDir returns the directory ctlptl uses to stash ctlptl-managed
+// state that doesn't live in a container, such as persisted Replication
+// specs.
+func configDir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "ctlptl"), nil
+}
@@ -0,0 +1,272 @@
+package replication
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fakeRegistry is a minimal in-memory stand-in for the Docker Registry
+// HTTP API V2, just enough of it to exercise Syncer.Run/mirrorDeletes
+// without a real registry container.
+type fakeRegistry struct {
+	mu sync.Mutex
+
+	tags      map[string][]string     // repo -> tags
+	manifests map[string]fakeManifest // "repo:tag" -> manifest
+	blobs     map[string][]byte       // "repo@digest" -> data
+
+	nextUpload int
+}
+
+type fakeManifest struct {
+	body      []byte
+	mediaType string
+	digest    string
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		tags:      map[string][]string{},
+		manifests: map[string]fakeManifest{},
+		blobs:     map[string][]byte{},
+	}
+}
+
+// seedImage plants a manifest (built deterministically from repo, tag,
+// and layers) and its config/layer blobs directly into the fake, so
+// tests can set up src/dst state without going through HTTP.
+func (f *fakeRegistry) seedImage(repo, tag string, layers ...string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m := testManifest{}
+	m.Config.Digest = "sha256:cfg-" + tag
+	for _, l := range layers {
+		m.Layers = append(m.Layers, testManifestLayer{Digest: l})
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	digest := fakeDigest(body)
+
+	f.blobs[repo+"@"+m.Config.Digest] = []byte("config-" + tag)
+	for _, l := range layers {
+		f.blobs[repo+"@"+l] = []byte("layer-" + l)
+	}
+
+	if _, exists := f.manifests[repo+":"+tag]; !exists {
+		f.tags[repo] = append(f.tags[repo], tag)
+	}
+	f.manifests[repo+":"+tag] = fakeManifest{
+		body:      body,
+		mediaType: "application/vnd.docker.distribution.manifest.v2+json",
+		digest:    digest,
+	}
+	return digest
+}
+
+type testManifestLayer struct {
+	Digest string `json:"digest"`
+}
+
+type testManifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Layers []testManifestLayer `json:"layers"`
+}
+
+func fakeDigest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+func (f *fakeRegistry) handle(w http.ResponseWriter, req *http.Request) {
+	path := req.URL.Path
+	switch {
+	case req.Method == http.MethodGet && path == "/v2/_catalog":
+		f.serveCatalog(w)
+	case req.Method == http.MethodGet && strings.HasSuffix(path, "/tags/list"):
+		repo := strings.TrimSuffix(strings.TrimPrefix(path, "/v2/"), "/tags/list")
+		f.serveTags(w, repo)
+	case strings.Contains(path, "/manifests/"):
+		repo, ref := splitRepoRef(path, "/manifests/")
+		switch req.Method {
+		case http.MethodGet:
+			f.serveGetManifest(w, repo, ref)
+		case http.MethodPut:
+			f.servePutManifest(w, req, repo, ref)
+		case http.MethodDelete:
+			f.serveDeleteManifest(w, repo, ref)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	case strings.HasSuffix(path, "/blobs/uploads/") && req.Method == http.MethodPost:
+		repo := strings.TrimSuffix(strings.TrimPrefix(path, "/v2/"), "/blobs/uploads/")
+		f.serveStartUpload(w, repo)
+	case strings.Contains(path, "/blobs/uploads/") && req.Method == http.MethodPut:
+		repo, _ := splitRepoRef(path, "/blobs/uploads/")
+		f.servePutBlob(w, req, repo)
+	case strings.Contains(path, "/blobs/"):
+		repo, digest := splitRepoRef(path, "/blobs/")
+		switch req.Method {
+		case http.MethodHead:
+			f.serveHeadBlob(w, repo, digest)
+		case http.MethodGet:
+			f.serveGetBlob(w, repo, digest)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func splitRepoRef(path, sep string) (repo, ref string) {
+	path = strings.TrimPrefix(path, "/v2/")
+	idx := strings.Index(path, sep)
+	if idx < 0 {
+		return path, ""
+	}
+	return path[:idx], path[idx+len(sep):]
+}
+
+func (f *fakeRegistry) serveCatalog(w http.ResponseWriter) {
+	f.mu.Lock()
+	repos := make([]string, 0, len(f.tags))
+	for repo := range f.tags {
+		repos = append(repos, repo)
+	}
+	f.mu.Unlock()
+	sort.Strings(repos)
+	_ = json.NewEncoder(w).Encode(map[string][]string{"repositories": repos})
+}
+
+func (f *fakeRegistry) serveTags(w http.ResponseWriter, repo string) {
+	f.mu.Lock()
+	tags := append([]string{}, f.tags[repo]...)
+	f.mu.Unlock()
+	sort.Strings(tags)
+	_ = json.NewEncoder(w).Encode(map[string][]string{"tags": tags})
+}
+
+func (f *fakeRegistry) manifestByRef(repo, ref string) (fakeManifest, bool) {
+	if m, ok := f.manifests[repo+":"+ref]; ok {
+		return m, true
+	}
+	for _, tag := range f.tags[repo] {
+		if m, ok := f.manifests[repo+":"+tag]; ok && m.digest == ref {
+			return m, true
+		}
+	}
+	return fakeManifest{}, false
+}
+
+func (f *fakeRegistry) serveGetManifest(w http.ResponseWriter, repo, ref string) {
+	f.mu.Lock()
+	m, ok := f.manifestByRef(repo, ref)
+	f.mu.Unlock()
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", m.mediaType)
+	w.Header().Set("Docker-Content-Digest", m.digest)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(m.body)
+}
+
+func (f *fakeRegistry) servePutManifest(w http.ResponseWriter, req *http.Request, repo, tag string) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	digest := fakeDigest(body)
+
+	f.mu.Lock()
+	if _, exists := f.manifests[repo+":"+tag]; !exists {
+		f.tags[repo] = append(f.tags[repo], tag)
+	}
+	f.manifests[repo+":"+tag] = fakeManifest{body: body, mediaType: req.Header.Get("Content-Type"), digest: digest}
+	f.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *fakeRegistry) serveDeleteManifest(w http.ResponseWriter, repo, digest string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var kept []string
+	deleted := false
+	for _, tag := range f.tags[repo] {
+		key := repo + ":" + tag
+		if f.manifests[key].digest == digest {
+			delete(f.manifests, key)
+			deleted = true
+			continue
+		}
+		kept = append(kept, tag)
+	}
+	f.tags[repo] = kept
+
+	if !deleted {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (f *fakeRegistry) serveStartUpload(w http.ResponseWriter, repo string) {
+	f.mu.Lock()
+	f.nextUpload++
+	id := f.nextUpload
+	f.mu.Unlock()
+
+	w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", repo, id))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (f *fakeRegistry) servePutBlob(w http.ResponseWriter, req *http.Request, repo string) {
+	digest := req.URL.Query().Get("digest")
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.blobs[repo+"@"+digest] = data
+	f.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *fakeRegistry) serveHeadBlob(w http.ResponseWriter, repo, digest string) {
+	f.mu.Lock()
+	_, ok := f.blobs[repo+"@"+digest]
+	f.mu.Unlock()
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *fakeRegistry) serveGetBlob(w http.ResponseWriter, repo, digest string) {
+	f.mu.Lock()
+	data, ok := f.blobs[repo+"@"+digest]
+	f.mu.Unlock()
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,216 @@
+package replication
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// registryClient speaks just enough of the Docker Registry HTTP API V2
+// to support replication: listing the catalog, listing tags, and
+// fetching/pushing manifests and blobs.
+type registryClient struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+func newRegistryClient(baseURL, username, password string) *registryClient {
+	return &registryClient{
+		baseURL:    baseURL,
+		username:   username,
+		password:   password,
+		httpClient: http.DefaultClient,
+	}
+}
+
+func (c *registryClient) do(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.httpClient.Do(req)
+}
+
+type catalogResponse struct {
+	Repositories []string `json:"repositories"`
+}
+
+// Catalog lists every repository in the registry, following the
+// Link-header based pagination the distribution API uses.
+func (c *registryClient) Catalog(ctx context.Context) ([]string, error) {
+	repos := []string{}
+	path := "/v2/_catalog?n=100"
+	for path != "" {
+		resp, err := c.do(ctx, http.MethodGet, path, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		var page catalogResponse
+		err = decodeAndClose(resp, &page)
+		if err != nil {
+			return nil, fmt.Errorf("listing catalog: %v", err)
+		}
+		repos = append(repos, page.Repositories...)
+		path = nextLink(resp)
+	}
+	return repos, nil
+}
+
+type tagsResponse struct {
+	Tags []string `json:"tags"`
+}
+
+// Tags lists every tag of repo.
+func (c *registryClient) Tags(ctx context.Context, repo string) ([]string, error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/tags/list", repo), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var page tagsResponse
+	if err := decodeAndClose(resp, &page); err != nil {
+		return nil, fmt.Errorf("listing tags for %s: %v", repo, err)
+	}
+	return page.Tags, nil
+}
+
+// Manifest fetches the raw manifest body, its content-type, and its
+// Docker-Content-Digest for repo:reference.
+func (c *registryClient) Manifest(ctx context.Context, repo, reference string) (body []byte, mediaType, digest string, err error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/manifests/%s", repo, reference), nil,
+		"application/vnd.docker.distribution.manifest.v2+json")
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", "", fmt.Errorf("fetching manifest %s:%s: status %d", repo, reference, resp.StatusCode)
+	}
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return body, resp.Header.Get("Content-Type"), resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// PutManifest pushes a manifest to repo:reference.
+func (c *registryClient) PutManifest(ctx context.Context, repo, reference string, body []byte, mediaType string) error {
+	resp, err := c.do(ctx, http.MethodPut, fmt.Sprintf("/v2/%s/manifests/%s", repo, reference), bytes.NewReader(body), mediaType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("pushing manifest %s:%s: status %d", repo, reference, resp.StatusCode)
+	}
+	return nil
+}
+
+// DeleteManifest removes repo:reference from the registry, for the
+// "mirror" delete policy. The delete API takes a digest, not a tag, so
+// reference is resolved to its digest first if needed.
+func (c *registryClient) DeleteManifest(ctx context.Context, repo, reference string) error {
+	_, _, digest, err := c.Manifest(ctx, repo, reference)
+	if err != nil {
+		return err
+	}
+	if digest == "" {
+		return fmt.Errorf("deleting %s:%s: no such manifest", repo, reference)
+	}
+
+	resp, err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/v2/%s/manifests/%s", repo, digest), nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("deleting %s:%s: status %d", repo, reference, resp.StatusCode)
+	}
+	return nil
+}
+
+// BlobExists checks whether repo already has a blob with the given digest,
+// so replication can skip re-uploading layers the destination already has.
+func (c *registryClient) BlobExists(ctx context.Context, repo, digest string) (bool, error) {
+	resp, err := c.do(ctx, http.MethodHead, fmt.Sprintf("/v2/%s/blobs/%s", repo, digest), nil, "")
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// Blob fetches the raw contents of a blob by digest.
+func (c *registryClient) Blob(ctx context.Context, repo, digest string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/blobs/%s", repo, digest), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching blob %s@%s: status %d", repo, digest, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// PutBlob uploads a blob using the registry's two-step monolithic upload:
+// POST to start a session, then PUT the contents with the digest.
+func (c *registryClient) PutBlob(ctx context.Context, repo, digest string, data []byte) error {
+	startResp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/v2/%s/blobs/uploads/", repo), nil, "")
+	if err != nil {
+		return err
+	}
+	location := startResp.Header.Get("Location")
+	startResp.Body.Close()
+	if location == "" {
+		return fmt.Errorf("starting blob upload for %s: no Location header returned", repo)
+	}
+
+	sep := "?"
+	if strings.Contains(location, "?") {
+		sep = "&"
+	}
+	putResp, err := c.do(ctx, http.MethodPut, fmt.Sprintf("%s%sdigest=%s", location, sep, digest), bytes.NewReader(data), "application/octet-stream")
+	if err != nil {
+		return err
+	}
+	defer putResp.Body.Close()
+	if putResp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("uploading blob %s@%s: status %d", repo, digest, putResp.StatusCode)
+	}
+	return nil
+}
+
+func decodeAndClose(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func nextLink(resp *http.Response) string {
+	// The distribution API returns pagination via an RFC5988 Link
+	// header, e.g. `</v2/_catalog?last=foo&n=100>; rel="next"`.
+	link := resp.Header.Get("Link")
+	start := strings.Index(link, "<")
+	end := strings.Index(link, ">")
+	if start < 0 || end <= start || !strings.Contains(link[end:], `rel="next"`) {
+		return ""
+	}
+	return link[start+1 : end]
+}
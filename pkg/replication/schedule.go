@@ -0,0 +1,28 @@
+package replication
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// nextRun returns the next time a 5-field cron schedule should fire
+// after `after`.
+func nextRun(schedule string, after time.Time) (time.Time, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(after), nil
+}
+
+// SleepDuration returns how long `ctlptl replication run` should sleep
+// before its next invocation of the schedule, given the current time.
+func SleepDuration(schedule string, now time.Time) (time.Duration, error) {
+	next, err := nextRun(schedule, now)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schedule %q: %v", schedule, err)
+	}
+	return next.Sub(now), nil
+}
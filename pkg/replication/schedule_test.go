@@ -0,0 +1,24 @@
+package replication
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSleepDuration(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	got, err := SleepDuration("0 10 * * *", now)
+	if err != nil {
+		t.Fatalf("SleepDuration: %v", err)
+	}
+	if want := time.Hour; got != want {
+		t.Fatalf("SleepDuration(\"0 10 * * *\", 09:00) = %v, want %v", got, want)
+	}
+}
+
+func TestSleepDuration_InvalidSchedule(t *testing.T) {
+	if _, err := SleepDuration("not a cron schedule", time.Now()); err == nil {
+		t.Fatal("SleepDuration with an invalid schedule should have returned an error")
+	}
+}
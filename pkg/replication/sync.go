@@ -0,0 +1,274 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+// Endpoint is the connection info needed to talk to one side of a
+// replication (either the source or destination registry).
+type Endpoint struct {
+	URL      string
+	Username string
+	Password string
+}
+
+// ProgressEvent is a single line of structured progress ctlptl writes to
+// stderr while a replication runs, so CI tooling can follow along
+// without scraping human-readable text.
+type ProgressEvent struct {
+	Time   time.Time `json:"time"`
+	Repo   string    `json:"repo,omitempty"`
+	Tag    string    `json:"tag,omitempty"`
+	Action string    `json:"action"` // "scan", "copy", "skip", "delete", "error", "done"
+	Error  string    `json:"error,omitempty"`
+}
+
+// Result summarizes one run of a replication.
+type Result struct {
+	ImagesCopied  int
+	ImagesSkipped int
+}
+
+// Syncer copies images from a source registry to a destination registry
+// according to a RegistryReplicationSpec.
+type Syncer struct {
+	progress io.Writer
+}
+
+// NewSyncer creates a Syncer that writes newline-delimited JSON
+// ProgressEvents to progress as it works.
+func NewSyncer(progress io.Writer) *Syncer {
+	return &Syncer{progress: progress}
+}
+
+func (s *Syncer) emit(ev ProgressEvent) {
+	if s.progress == nil {
+		return
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	_, _ = s.progress.Write(append(data, '\n'))
+}
+
+// Run walks src's catalog, diffs each repo:tag matching spec.Filter
+// against dst, and copies anything missing from dst.
+//
+// In "push" mode (the default), this proactively copies every matching
+// image on every run. In "pull-through" mode, the destination is
+// expected to be configured as a pull-through cache of the source
+// itself (see RegistryProxySpec) and fetches images lazily on demand,
+// so Run has nothing to proactively copy and returns immediately.
+//
+// With DeletePolicy "mirror", any image in dst's scanned repos that no
+// longer matches an image on src is deleted from dst after the copy
+// pass; "keep" (the default) never deletes anything.
+func (s *Syncer) Run(ctx context.Context, spec api.RegistryReplicationSpec, src, dst Endpoint) (Result, error) {
+	result := Result{}
+
+	switch spec.Mode {
+	case "", "push", "pull-through":
+	default:
+		return result, fmt.Errorf("replication: invalid mode %q, must be \"push\" or \"pull-through\"", spec.Mode)
+	}
+	switch spec.DeletePolicy {
+	case "", "keep", "mirror":
+	default:
+		return result, fmt.Errorf("replication: invalid delete policy %q, must be \"keep\" or \"mirror\"", spec.DeletePolicy)
+	}
+
+	if spec.Mode == "pull-through" {
+		s.emit(ProgressEvent{Time: timeNow(), Action: "done"})
+		return result, nil
+	}
+
+	srcClient := newRegistryClient(src.URL, src.Username, src.Password)
+	dstClient := newRegistryClient(dst.URL, dst.Username, dst.Password)
+
+	repos, err := srcClient.Catalog(ctx)
+	if err != nil {
+		return result, fmt.Errorf("replication: %v", err)
+	}
+
+	srcRefs := map[string]bool{}
+	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		tags, err := srcClient.Tags(ctx, repo)
+		if err != nil {
+			s.emit(ProgressEvent{Time: timeNow(), Repo: repo, Action: "error", Error: err.Error()})
+			continue
+		}
+
+		for _, tag := range tags {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+
+			ref := repo + ":" + tag
+			if spec.Filter != "" {
+				matched, err := path.Match(spec.Filter, ref)
+				if err != nil {
+					return result, fmt.Errorf("replication: invalid filter %q: %v", spec.Filter, err)
+				}
+				if !matched {
+					continue
+				}
+			}
+			srcRefs[ref] = true
+
+			s.emit(ProgressEvent{Time: timeNow(), Repo: repo, Tag: tag, Action: "scan"})
+
+			copied, err := s.copyIfMissing(ctx, srcClient, dstClient, repo, tag)
+			if err != nil {
+				s.emit(ProgressEvent{Time: timeNow(), Repo: repo, Tag: tag, Action: "error", Error: err.Error()})
+				continue
+			}
+			if copied {
+				result.ImagesCopied++
+				s.emit(ProgressEvent{Time: timeNow(), Repo: repo, Tag: tag, Action: "copy"})
+			} else {
+				result.ImagesSkipped++
+				s.emit(ProgressEvent{Time: timeNow(), Repo: repo, Tag: tag, Action: "skip"})
+			}
+		}
+	}
+
+	if spec.DeletePolicy == "mirror" {
+		// A repo removed from src entirely (not just down to zero
+		// matching tags) never shows up in `repos`, so sweep dst's own
+		// catalog too -- otherwise its images are never deleted.
+		dstRepos, err := dstClient.Catalog(ctx)
+		if err != nil {
+			return result, fmt.Errorf("replication: %v", err)
+		}
+		if err := s.mirrorDeletes(ctx, dstClient, unionRepos(repos, dstRepos), spec.Filter, srcRefs); err != nil {
+			return result, err
+		}
+	}
+
+	s.emit(ProgressEvent{Time: timeNow(), Action: "done"})
+	return result, nil
+}
+
+// unionRepos returns the deduplicated union of a and b.
+func unionRepos(a, b []string) []string {
+	seen := map[string]bool{}
+	union := make([]string, 0, len(a)+len(b))
+	for _, repo := range append(append([]string{}, a...), b...) {
+		if seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		union = append(union, repo)
+	}
+	return union
+}
+
+// mirrorDeletes removes every repo:tag in dst's scanned repos that
+// matches spec.Filter (if set) but isn't in srcRefs, implementing
+// DeletePolicy "mirror".
+func (s *Syncer) mirrorDeletes(ctx context.Context, dst *registryClient, repos []string, filter string, srcRefs map[string]bool) error {
+	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		tags, err := dst.Tags(ctx, repo)
+		if err != nil {
+			s.emit(ProgressEvent{Time: timeNow(), Repo: repo, Action: "error", Error: err.Error()})
+			continue
+		}
+
+		for _, tag := range tags {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			ref := repo + ":" + tag
+			if filter != "" {
+				matched, err := path.Match(filter, ref)
+				if err != nil {
+					return fmt.Errorf("replication: invalid filter %q: %v", filter, err)
+				}
+				if !matched {
+					continue
+				}
+			}
+			if srcRefs[ref] {
+				continue
+			}
+
+			if err := dst.DeleteManifest(ctx, repo, tag); err != nil {
+				s.emit(ProgressEvent{Time: timeNow(), Repo: repo, Tag: tag, Action: "error", Error: err.Error()})
+				continue
+			}
+			s.emit(ProgressEvent{Time: timeNow(), Repo: repo, Tag: tag, Action: "delete"})
+		}
+	}
+	return nil
+}
+
+// copyIfMissing copies repo:tag from src to dst if dst doesn't already
+// have a manifest with the same digest, returning whether a copy happened.
+func (s *Syncer) copyIfMissing(ctx context.Context, src, dst *registryClient, repo, tag string) (bool, error) {
+	srcBody, mediaType, srcDigest, err := src.Manifest(ctx, repo, tag)
+	if err != nil {
+		return false, err
+	}
+	if srcBody == nil {
+		return false, fmt.Errorf("source manifest %s:%s disappeared mid-sync", repo, tag)
+	}
+
+	_, _, dstDigest, err := dst.Manifest(ctx, repo, tag)
+	if err != nil {
+		return false, err
+	}
+	if dstDigest != "" && dstDigest == srcDigest {
+		return false, nil
+	}
+
+	layerDigests, err := registry.ManifestLayerDigests(srcBody)
+	if err != nil {
+		return false, err
+	}
+
+	for _, layerDigest := range layerDigests {
+		exists, err := dst.BlobExists(ctx, repo, layerDigest)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			continue
+		}
+		blob, err := src.Blob(ctx, repo, layerDigest)
+		if err != nil {
+			return false, err
+		}
+		if err := dst.PutBlob(ctx, repo, layerDigest, blob); err != nil {
+			return false, err
+		}
+	}
+
+	if err := dst.PutManifest(ctx, repo, tag, srcBody, mediaType); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// timeNow exists so the rest of the package doesn't call time.Now()
+// directly in more than one place.
+func timeNow() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,117 @@
+package replication
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+func TestSyncer_Run_PushCopiesMissingImages(t *testing.T) {
+	src := newFakeRegistry()
+	src.seedImage("library/nginx", "latest", "sha256:layer-1")
+	srcSrv := httptest.NewServer(http.HandlerFunc(src.handle))
+	defer srcSrv.Close()
+
+	dst := newFakeRegistry()
+	dstSrv := httptest.NewServer(http.HandlerFunc(dst.handle))
+	defer dstSrv.Close()
+
+	syncer := NewSyncer(io.Discard)
+	result, err := syncer.Run(context.Background(), api.RegistryReplicationSpec{Mode: "push", DeletePolicy: "keep"},
+		Endpoint{URL: srcSrv.URL}, Endpoint{URL: dstSrv.URL})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.ImagesCopied != 1 || result.ImagesSkipped != 0 {
+		t.Fatalf("Run result = %+v, want 1 copied, 0 skipped", result)
+	}
+
+	dst.mu.Lock()
+	_, ok := dst.manifests["library/nginx:latest"]
+	dst.mu.Unlock()
+	if !ok {
+		t.Fatal("destination is missing the replicated manifest")
+	}
+}
+
+func TestSyncer_Run_SkipsUpToDateImages(t *testing.T) {
+	src := newFakeRegistry()
+	src.seedImage("library/nginx", "latest", "sha256:layer-1")
+	srcSrv := httptest.NewServer(http.HandlerFunc(src.handle))
+	defer srcSrv.Close()
+
+	dst := newFakeRegistry()
+	dst.seedImage("library/nginx", "latest", "sha256:layer-1")
+	dstSrv := httptest.NewServer(http.HandlerFunc(dst.handle))
+	defer dstSrv.Close()
+
+	syncer := NewSyncer(io.Discard)
+	result, err := syncer.Run(context.Background(), api.RegistryReplicationSpec{Mode: "push", DeletePolicy: "keep"},
+		Endpoint{URL: srcSrv.URL}, Endpoint{URL: dstSrv.URL})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.ImagesCopied != 0 || result.ImagesSkipped != 1 {
+		t.Fatalf("Run result = %+v, want 0 copied, 1 skipped", result)
+	}
+}
+
+func TestSyncer_Run_PullThroughSkipsProactiveCopy(t *testing.T) {
+	syncer := NewSyncer(io.Discard)
+	// Deliberately point at endpoints nothing is listening on, to prove
+	// pull-through mode doesn't even try to talk to either registry.
+	result, err := syncer.Run(context.Background(), api.RegistryReplicationSpec{Mode: "pull-through"},
+		Endpoint{URL: "http://127.0.0.1:0"}, Endpoint{URL: "http://127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result != (Result{}) {
+		t.Fatalf("Run result = %+v, want the zero value", result)
+	}
+}
+
+func TestSyncer_Run_RejectsInvalidModeAndDeletePolicy(t *testing.T) {
+	syncer := NewSyncer(io.Discard)
+	ctx := context.Background()
+	ep := Endpoint{URL: "http://127.0.0.1:0"}
+
+	if _, err := syncer.Run(ctx, api.RegistryReplicationSpec{Mode: "bogus"}, ep, ep); err == nil {
+		t.Fatal("Run with an invalid Mode should have returned an error")
+	}
+	if _, err := syncer.Run(ctx, api.RegistryReplicationSpec{DeletePolicy: "bogus"}, ep, ep); err == nil {
+		t.Fatal("Run with an invalid DeletePolicy should have returned an error")
+	}
+}
+
+func TestSyncer_Run_MirrorDeletesImagesFromRemovedSourceRepo(t *testing.T) {
+	src := newFakeRegistry()
+	src.seedImage("library/nginx", "latest", "sha256:layer-1")
+	srcSrv := httptest.NewServer(http.HandlerFunc(src.handle))
+	defer srcSrv.Close()
+
+	dst := newFakeRegistry()
+	dst.seedImage("library/nginx", "latest", "sha256:layer-1")
+	// library/old used to be replicated, but no longer appears in src's
+	// catalog at all -- not even with zero tags.
+	dst.seedImage("library/old", "latest", "sha256:layer-2")
+	dstSrv := httptest.NewServer(http.HandlerFunc(dst.handle))
+	defer dstSrv.Close()
+
+	syncer := NewSyncer(io.Discard)
+	_, err := syncer.Run(context.Background(), api.RegistryReplicationSpec{Mode: "push", DeletePolicy: "mirror"},
+		Endpoint{URL: srcSrv.URL}, Endpoint{URL: dstSrv.URL})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	dst.mu.Lock()
+	_, stillThere := dst.manifests["library/old:latest"]
+	dst.mu.Unlock()
+	if stillThere {
+		t.Fatal("mirror delete should have removed library/old:latest, which no longer exists on the source at all")
+	}
+}